@@ -0,0 +1,162 @@
+package outboundgroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/Dreamacro/clash/adapter/outbound"
+	"github.com/Dreamacro/clash/adapter/provider"
+	"github.com/Dreamacro/clash/common/singledo"
+	"github.com/Dreamacro/clash/component/dialer"
+	"github.com/Dreamacro/clash/component/profile/cachefile"
+	C "github.com/Dreamacro/clash/constant"
+	types "github.com/Dreamacro/clash/constant/provider"
+)
+
+type Fallback struct {
+	*outbound.Base
+	disableUDP bool
+	single     *singledo.Single[[]C.Proxy]
+	providers  []types.ProxyProvider
+
+	selectedMu sync.Mutex
+	selected   string
+}
+
+func (f *Fallback) getSelected() string {
+	f.selectedMu.Lock()
+	defer f.selectedMu.Unlock()
+	return f.selected
+}
+
+func (f *Fallback) Now() string {
+	proxy := f.findAliveProxy(false)
+	return proxy.Name()
+}
+
+func (f *Fallback) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
+	c, err := f.findAliveProxy(true).DialContext(ctx, metadata, f.Base.DialOptions(opts...)...)
+	if err == nil {
+		c.AppendToChains(f)
+	}
+	return c, err
+}
+
+func (f *Fallback) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
+	pc, err := f.findAliveProxy(true).ListenPacketContext(ctx, metadata, f.Base.DialOptions(opts...)...)
+	if err == nil {
+		pc.AppendToChains(f)
+	}
+	return pc, err
+}
+
+// Set implements C.ProxySelector, pinning the preferred proxy for subsequent
+// dials. The pin is persisted so it survives a restart.
+func (f *Fallback) Set(name string) error {
+	for _, proxy := range f.GetProxies(false) {
+		if proxy.Name() == name {
+			f.selectedMu.Lock()
+			f.selected = name
+			f.selectedMu.Unlock()
+			cachefile.Cache().SetSelected(f.Name(), name)
+			return nil
+		}
+	}
+
+	return errors.New("proxy not exist")
+}
+
+// SupportUDP implements C.ProxyAdapter
+func (f *Fallback) SupportUDP() bool {
+	if f.disableUDP {
+		return false
+	}
+
+	proxy := f.findAliveProxy(false)
+	return proxy.SupportUDP()
+}
+
+// MarshalJSON implements C.ProxyAdapter
+func (f *Fallback) MarshalJSON() ([]byte, error) {
+	all := []string{}
+	for _, proxy := range f.GetProxies(false) {
+		all = append(all, proxy.Name())
+	}
+	return json.Marshal(map[string]any{
+		"type": f.Type().String(),
+		"now":  f.Now(),
+		"all":  all,
+	})
+}
+
+// findAliveProxy walks the declared proxy order once, remembering the first
+// alive proxy and the alive proxy with the best composite health-check
+// score while looking for the pinned selection. The pinned proxy wins if it
+// is alive; otherwise dialing prefers the best-scored alive proxy, falling
+// back to the first alive proxy in declared order when no alive proxy has
+// been scored yet.
+func (f *Fallback) findAliveProxy(touch bool) C.Proxy {
+	proxies := f.GetProxies(touch)
+	selected := f.getSelected()
+
+	var firstAlive, best C.Proxy
+	var bestScore uint16
+	for _, proxy := range proxies {
+		if !proxy.Alive() {
+			continue
+		}
+
+		if firstAlive == nil {
+			firstAlive = proxy
+		}
+
+		if selected != "" && proxy.Name() == selected {
+			return proxy
+		}
+
+		if score, ok := provider.Score(proxy.Name()); ok && (best == nil || score < bestScore) {
+			best = proxy
+			bestScore = score
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+	if firstAlive != nil {
+		return firstAlive
+	}
+
+	return proxies[0]
+}
+
+// GetProxies implements C.GroupAdapter
+func (f *Fallback) GetProxies(touch bool) []C.Proxy {
+	elm, _, _ := f.single.Do(func() ([]C.Proxy, error) {
+		return getProvidersProxies(f.providers, touch), nil
+	})
+
+	return elm
+}
+
+func NewFallback(option *GroupCommonOption, providers []types.ProxyProvider) *Fallback {
+	fallback := &Fallback{
+		Base: outbound.NewBase(outbound.BaseOption{
+			Name:        option.Name,
+			Type:        C.Fallback,
+			Interface:   option.Interface,
+			RoutingMark: option.RoutingMark,
+		}),
+		single:     singledo.NewSingle[[]C.Proxy](defaultGetProxiesDuration),
+		providers:  providers,
+		disableUDP: option.DisableUDP,
+	}
+
+	if selected, ok := cachefile.Cache().SelectedMap()[option.Name]; ok {
+		fallback.selected = selected
+	}
+
+	return fallback
+}
@@ -0,0 +1,299 @@
+package outboundgroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Dreamacro/clash/adapter/outbound"
+	"github.com/Dreamacro/clash/adapter/provider"
+	"github.com/Dreamacro/clash/common/murmur3"
+	"github.com/Dreamacro/clash/common/singledo"
+	"github.com/Dreamacro/clash/component/dialer"
+	C "github.com/Dreamacro/clash/constant"
+	types "github.com/Dreamacro/clash/constant/provider"
+)
+
+var errStrategy = errors.New("unsupported strategy")
+
+type strategyFn = func(proxies []C.Proxy, metadata *C.Metadata) C.Proxy
+
+type LoadBalance struct {
+	*outbound.Base
+	disableUDP bool
+	single     *singledo.Single[[]C.Proxy]
+	maxRetry   int
+	strategyFn strategyFn
+	providers  []types.ProxyProvider
+}
+
+func parseStrategy(config map[string]any) string {
+	if elm, ok := config["strategy"]; ok {
+		if strategy, ok := elm.(string); ok {
+			return strategy
+		}
+	}
+	return "consistent-hashing"
+}
+
+func getKey(metadata *C.Metadata) string {
+	if metadata == nil {
+		return ""
+	} else if metadata.Host != "" {
+		return metadata.Host
+	} else if metadata.DstIP.IsValid() {
+		return metadata.DstIP.String()
+	}
+	return ""
+}
+
+func jumpHash(key uint64, buckets int32) int32 {
+	var b, j int64
+
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+
+	return int32(b)
+}
+
+func strategyRoundRobin() strategyFn {
+	idx := 0
+	var mu sync.Mutex
+	return func(proxies []C.Proxy, metadata *C.Metadata) C.Proxy {
+		mu.Lock()
+		defer mu.Unlock()
+		length := len(proxies)
+		for i := 0; i < length; i++ {
+			idx = (idx + 1) % length
+			proxy := proxies[idx]
+			if proxy.Alive() {
+				return proxy
+			}
+		}
+		return proxies[0]
+	}
+}
+
+func strategyConsistentHashing() strategyFn {
+	maxRetry := 5
+	return func(proxies []C.Proxy, metadata *C.Metadata) C.Proxy {
+		key := uint64(murmur3.Sum32([]byte(getKey(metadata))))
+		buckets := int32(len(proxies))
+		for i := 0; i < maxRetry; i++ {
+			idx := jumpHash(key+uint64(i), buckets)
+			proxy := proxies[idx]
+			if proxy.Alive() {
+				return proxy
+			}
+			key = key * 2862933555777941757
+		}
+		return proxies[0]
+	}
+}
+
+// proxyLoad tracks the state p2c needs that the HealthCheck subsystem
+// doesn't already expose: how many connections a proxy currently has open.
+type proxyLoad struct {
+	inflight int64
+}
+
+// trackingConn decrements the owning proxy's inflight counter when closed,
+// mirroring the increment done on dial.
+type trackingConn struct {
+	C.Conn
+	load *proxyLoad
+	once sync.Once
+}
+
+func (c *trackingConn) Close() error {
+	c.once.Do(func() {
+		atomic.AddInt64(&c.load.inflight, -1)
+	})
+	return c.Conn.Close()
+}
+
+// pickTwoDistinct returns two distinct indices into [0,n), chosen uniformly
+// at random, for n >= 2 candidates.
+func pickTwoDistinct(n int) (int, int) {
+	ai := rand.Intn(n)
+	bi := rand.Intn(n - 1)
+	if bi >= ai {
+		bi++
+	}
+	return ai, bi
+}
+
+type p2cPick int
+
+const (
+	p2cPickA p2cPick = iota
+	p2cPickB
+	p2cPickFallback
+)
+
+// p2cChoice is the decision core of strategyP2C: given whether each
+// candidate's score was computable, pick the lower-scoring one, or signal a
+// fallback to round-robin when either lacks usable RTT data, or they tie.
+func p2cChoice(scoreA float64, okA bool, scoreB float64, okB bool) p2cPick {
+	switch {
+	case !okA || !okB:
+		return p2cPickFallback
+	case scoreA == scoreB:
+		return p2cPickFallback
+	case scoreA < scoreB:
+		return p2cPickA
+	default:
+		return p2cPickB
+	}
+}
+
+// strategyP2C implements "power of two choices": two alive proxies are
+// sampled uniformly at random and the one minimizing rtt_ms * (1 + inflight)
+// is dialed. It falls back to round-robin when RTT data is missing or tied,
+// so a single cold proxy can't be starved forever.
+func strategyP2C() strategyFn {
+	rr := strategyRoundRobin()
+
+	score := func(proxy C.Proxy) (float64, bool) {
+		rtt := proxy.LastDelay()
+		if composite, ok := provider.Score(proxy.Name()); ok {
+			rtt = composite
+		}
+		if rtt == 0 {
+			return 0, false
+		}
+		inflight := atomic.LoadInt64(&loadFor(proxy).inflight)
+		return float64(rtt) * (1 + float64(inflight)), true
+	}
+
+	return func(proxies []C.Proxy, metadata *C.Metadata) C.Proxy {
+		alive := make([]C.Proxy, 0, len(proxies))
+		for _, proxy := range proxies {
+			if proxy.Alive() {
+				alive = append(alive, proxy)
+			}
+		}
+
+		if len(alive) == 0 {
+			return proxies[0]
+		}
+		if len(alive) == 1 {
+			return alive[0]
+		}
+
+		ai, bi := pickTwoDistinct(len(alive))
+		a, b := alive[ai], alive[bi]
+
+		scoreA, okA := score(a)
+		scoreB, okB := score(b)
+
+		switch p2cChoice(scoreA, okA, scoreB, okB) {
+		case p2cPickA:
+			return a
+		case p2cPickB:
+			return b
+		default:
+			return rr(proxies, metadata)
+		}
+	}
+}
+
+func (lb *LoadBalance) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
+	proxy := lb.Next(metadata)
+	c, err := proxy.DialContext(ctx, metadata, lb.Base.DialOptions(opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.AppendToChains(lb)
+
+	l := loadFor(proxy)
+	atomic.AddInt64(&l.inflight, 1)
+
+	return &trackingConn{Conn: c, load: l}, nil
+}
+
+// loadFor returns the shared inflight counter for a proxy, keyed by name so
+// it is stable across GetProxies() re-fetching the underlying slice.
+func loadFor(proxy C.Proxy) *proxyLoad {
+	v, _ := proxyLoads.LoadOrStore(proxy.Name(), &proxyLoad{})
+	return v.(*proxyLoad)
+}
+
+// proxyLoads tracks per-proxy inflight connection counts for the p2c
+// strategy across the lifetime of the process.
+var proxyLoads sync.Map
+
+func (lb *LoadBalance) ListenPacketContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.PacketConn, error) {
+	proxy := lb.Next(metadata)
+	pc, err := proxy.ListenPacketContext(ctx, metadata, lb.Base.DialOptions(opts...)...)
+	if err == nil {
+		pc.AppendToChains(lb)
+	}
+	return pc, err
+}
+
+// Next selects the proxy for this dial using the configured strategy.
+func (lb *LoadBalance) Next(metadata *C.Metadata) C.Proxy {
+	proxies := lb.GetProxies(true)
+	return lb.strategyFn(proxies, metadata)
+}
+
+// SupportUDP implements C.ProxyAdapter
+func (lb *LoadBalance) SupportUDP() bool {
+	return !lb.disableUDP
+}
+
+// MarshalJSON implements C.ProxyAdapter
+func (lb *LoadBalance) MarshalJSON() ([]byte, error) {
+	all := []string{}
+	for _, proxy := range lb.GetProxies(false) {
+		all = append(all, proxy.Name())
+	}
+	return json.Marshal(map[string]any{
+		"type": lb.Type().String(),
+		"all":  all,
+	})
+}
+
+// GetProxies implements C.GroupAdapter
+func (lb *LoadBalance) GetProxies(touch bool) []C.Proxy {
+	elm, _, _ := lb.single.Do(func() ([]C.Proxy, error) {
+		return getProvidersProxies(lb.providers, touch), nil
+	})
+
+	return elm
+}
+
+func NewLoadBalance(option *GroupCommonOption, providers []types.ProxyProvider, strategy string) (*LoadBalance, error) {
+	var strategyFn strategyFn
+	switch strategy {
+	case "consistent-hashing":
+		strategyFn = strategyConsistentHashing()
+	case "round-robin":
+		strategyFn = strategyRoundRobin()
+	case "p2c":
+		strategyFn = strategyP2C()
+	default:
+		return nil, fmt.Errorf("%w: %s", errStrategy, strategy)
+	}
+
+	return &LoadBalance{
+		Base: outbound.NewBase(outbound.BaseOption{
+			Name:        option.Name,
+			Type:        C.LoadBalance,
+			Interface:   option.Interface,
+			RoutingMark: option.RoutingMark,
+		}),
+		single:     singledo.NewSingle[[]C.Proxy](defaultGetProxiesDuration),
+		providers:  providers,
+		strategyFn: strategyFn,
+		disableUDP: option.DisableUDP,
+	}, nil
+}
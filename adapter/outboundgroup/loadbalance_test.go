@@ -0,0 +1,41 @@
+package outboundgroup
+
+import "testing"
+
+func TestP2CChoice(t *testing.T) {
+	tests := []struct {
+		name   string
+		scoreA float64
+		okA    bool
+		scoreB float64
+		okB    bool
+		want   p2cPick
+	}{
+		{"both missing RTT falls back", 0, false, 0, false, p2cPickFallback},
+		{"A missing RTT falls back", 0, false, 5, true, p2cPickFallback},
+		{"B missing RTT falls back", 5, true, 0, false, p2cPickFallback},
+		{"tie falls back", 5, true, 5, true, p2cPickFallback},
+		{"lower score A wins", 2, true, 5, true, p2cPickA},
+		{"lower score B wins", 5, true, 2, true, p2cPickB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p2cChoice(tt.scoreA, tt.okA, tt.scoreB, tt.okB); got != tt.want {
+				t.Errorf("p2cChoice(%v, %v, %v, %v) = %v, want %v", tt.scoreA, tt.okA, tt.scoreB, tt.okB, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickTwoDistinct(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		ai, bi := pickTwoDistinct(2)
+		if ai == bi {
+			t.Fatalf("pickTwoDistinct(2) returned equal indices: %d, %d", ai, bi)
+		}
+		if ai < 0 || ai >= 2 || bi < 0 || bi >= 2 {
+			t.Fatalf("pickTwoDistinct(2) returned out-of-range indices: %d, %d", ai, bi)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/Dreamacro/clash/adapter/outbound"
 	"github.com/Dreamacro/clash/adapter/provider"
@@ -27,11 +29,19 @@ type GroupCommonOption struct {
 	Proxies    []string `group:"proxies,omitempty"`
 	Use        []string `group:"use,omitempty"`
 	URL        string   `group:"url,omitempty"`
+	Timeout    int      `group:"timeout,omitempty"` // milliseconds, per URL
 	Interval   int      `group:"interval,omitempty"`
+	SmartTTL   int      `group:"smart-ttl,omitempty"` // seconds; smart-group entry lifetime, defaults to smartDefaultTTL
 	Lazy       bool     `group:"lazy,omitempty"`
 	DisableUDP bool     `group:"disable-udp,omitempty"`
 	DisableDNS bool     `group:"disable-dns,omitempty"`
-	Filter     string   `group:"filter,omitempty"`
+}
+
+// proxyFilter is the resolved include/exclude regex pair a single provider
+// wrapped by `use` is filtered through.
+type proxyFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
 }
 
 func ParseProxyGroup(
@@ -45,7 +55,19 @@ func ParseProxyGroup(
 		Lazy: true,
 	}
 
-	if err := decoder.Decode(config, groupOption); err != nil {
+	// `url` may be a single string (decoded normally below) or a list of
+	// URLs for multi-URL health checking; hide the list shape from the
+	// generic decoder and resolve it separately via parseHealthCheckURLs.
+	decodeConfig := config
+	if _, isList := config["url"].([]any); isList {
+		decodeConfig = make(map[string]any, len(config))
+		for k, v := range config {
+			decodeConfig[k] = v
+		}
+		delete(decodeConfig, "url")
+	}
+
+	if err := decoder.Decode(decodeConfig, groupOption); err != nil {
 		return nil, errFormat
 	}
 
@@ -53,17 +75,16 @@ func ParseProxyGroup(
 		return nil, errFormat
 	}
 
-	var (
-		groupName  = groupOption.Name
-		filterRegx *regexp.Regexp
-	)
+	groupName := groupOption.Name
 
-	if groupOption.Filter != "" {
-		regx, err := regexp.Compile(groupOption.Filter)
-		if err != nil {
-			return nil, fmt.Errorf("%s: invalid filter regex: %w", groupName, err)
-		}
-		filterRegx = regx
+	healthCheckURLs, err := parseHealthCheckURLs(config["url"], groupOption.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", groupName, err)
+	}
+
+	filters, err := parseProxyFilters(config, groupOption.Use)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", groupName, err)
 	}
 
 	if len(groupOption.Proxies) == 0 && len(groupOption.Use) == 0 {
@@ -82,7 +103,7 @@ func ParseProxyGroup(
 			return nil, fmt.Errorf("%s: %w", groupName, errDuplicateProvider)
 		}
 
-		hc, err := newHealthCheck(ps, groupOption)
+		hc, err := newHealthCheck(ps, groupOption, healthCheckURLs)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", groupName, err)
 		}
@@ -97,7 +118,7 @@ func ParseProxyGroup(
 	}
 
 	if len(groupOption.Use) != 0 {
-		list, err := getProviders(providersMap, groupOption, filterRegx)
+		list, err := getProviders(providersMap, groupOption, filters, healthCheckURLs)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", groupName, err)
 		}
@@ -114,6 +135,9 @@ func ParseProxyGroup(
 	case "url-test":
 		opts := parseURLTestOption(config)
 		group = NewURLTest(groupOption, providers, opts...)
+	case "smart":
+		opts := parseURLTestOption(config)
+		group = NewSmart(groupOption, providers, opts...)
 	case "select":
 		group = NewSelector(groupOption, providers)
 	case "fallback":
@@ -145,7 +169,8 @@ func getProxies(mapping map[string]C.Proxy, list []string) ([]C.Proxy, error) {
 func getProviders(
 	mapping map[string]types.ProxyProvider,
 	groupOption *GroupCommonOption,
-	filterRegx *regexp.Regexp,
+	filters map[string]*proxyFilter,
+	healthCheckURLs []string,
 ) ([]types.ProxyProvider, error) {
 	var ps []types.ProxyProvider
 	for _, name := range groupOption.Use {
@@ -159,30 +184,150 @@ func getProviders(
 			return nil, fmt.Errorf("proxy group %s can't contains in `use`", name)
 		}
 
-		hc, err := newHealthCheck([]C.Proxy{}, groupOption)
+		hc, err := newHealthCheck([]C.Proxy{}, groupOption, healthCheckURLs)
 		if err != nil {
 			return nil, err
 		}
 
+		filter := filters[name]
 		fpName := fmt.Sprintf("%s-in-%s", name, groupOption.Name)
-		fp := provider.NewProxyFilterProvider(fpName, pp, hc, filterRegx)
+		fp := provider.NewProxyFilterProvider(fpName, pp, hc, filter.include, filter.exclude)
 		pp.RegisterProvidersInUse(fp)
 		ps = append(ps, fp)
 	}
 	return ps, nil
 }
 
-func newHealthCheck(ps []C.Proxy, groupOption *GroupCommonOption) (*provider.HealthCheck, error) {
+// parseProxyFilters resolves the include/exclude regex pair each provider
+// named in `use` is filtered through. `filter` and `exclude` may each be a
+// single string applied to every provider, or a map keyed by provider name
+// so one group can carve out a different subset per provider. A `!` prefix
+// on a `filter` entry is shorthand for "drop matches" instead of repeating
+// the pattern under `exclude`.
+func parseProxyFilters(config map[string]any, use []string) (map[string]*proxyFilter, error) {
+	filters := make(map[string]*proxyFilter, len(use))
+	for _, name := range use {
+		filters[name] = &proxyFilter{}
+	}
+
+	compile := func(pattern string) (*regexp.Regexp, error) {
+		regx, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter regex %q: %w", pattern, err)
+		}
+		return regx, nil
+	}
+
+	setFilter := func(name, pattern string, exclude bool) error {
+		if pattern == "" {
+			return nil
+		}
+
+		f, ok := filters[name]
+		if !ok {
+			return fmt.Errorf("filter: %q is not in `use`", name)
+		}
+
+		if strings.HasPrefix(pattern, "!") {
+			exclude = true
+			pattern = pattern[1:]
+		}
+
+		regx, err := compile(pattern)
+		if err != nil {
+			return err
+		}
+
+		if exclude {
+			f.exclude = regx
+		} else {
+			f.include = regx
+		}
+		return nil
+	}
+
+	apply := func(raw any, exclude bool) error {
+		switch v := raw.(type) {
+		case string:
+			for _, name := range use {
+				if err := setFilter(name, v, exclude); err != nil {
+					return err
+				}
+			}
+		case map[string]any:
+			for name, pattern := range v {
+				s, ok := pattern.(string)
+				if !ok {
+					return fmt.Errorf("filter[%s]: %w", name, errFormat)
+				}
+				if err := setFilter(name, s, exclude); err != nil {
+					return err
+				}
+			}
+		default:
+			return errFormat
+		}
+		return nil
+	}
+
+	if raw, ok := config["filter"]; ok {
+		if err := apply(raw, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := config["exclude"]; ok {
+		if err := apply(raw, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return filters, nil
+}
+
+// parseHealthCheckURLs resolves the `url` option, which may be a single
+// string (the common case, also reachable via the already-decoded
+// `fallback` value) or a list of URLs checked round-robin each interval so
+// a proxy that reaches one endpoint but not another is still caught.
+func parseHealthCheckURLs(raw any, fallback string) ([]string, error) {
+	switch v := raw.(type) {
+	case nil:
+		if fallback == "" {
+			return nil, nil
+		}
+		return []string{fallback}, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case []any:
+		urls := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("url: %w", errFormat)
+			}
+			urls = append(urls, s)
+		}
+		return urls, nil
+	default:
+		return nil, fmt.Errorf("url: %w", errFormat)
+	}
+}
+
+func newHealthCheck(ps []C.Proxy, groupOption *GroupCommonOption, urls []string) (*provider.HealthCheck, error) {
 	var hc *provider.HealthCheck
 
 	// select don't need health check
 	if groupOption.Type == "select" || groupOption.Type == "relay" {
-		hc = provider.NewHealthCheck(ps, "", 0, true)
+		hc = provider.NewHealthCheck(ps, nil, 0, true, 0)
 	} else {
-		if groupOption.URL == "" || groupOption.Interval == 0 {
+		if len(urls) == 0 || groupOption.Interval == 0 {
 			return nil, errMissHealthCheck
 		}
-		hc = provider.NewHealthCheck(ps, groupOption.URL, uint(groupOption.Interval), groupOption.Lazy)
+		timeout := time.Duration(groupOption.Timeout) * time.Millisecond
+		hc = provider.NewHealthCheck(ps, urls, uint(groupOption.Interval), groupOption.Lazy, timeout)
 	}
 	return hc, nil
 }
@@ -0,0 +1,140 @@
+package outboundgroup
+
+import "testing"
+
+func TestParseProxyFilters(t *testing.T) {
+	use := []string{"provider1", "provider2"}
+
+	t.Run("single string applies include to every provider", func(t *testing.T) {
+		config := map[string]any{"filter": "HK"}
+		filters, err := parseProxyFilters(config, use)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, name := range use {
+			f := filters[name]
+			if f.include == nil || !f.include.MatchString("HK-1") {
+				t.Errorf("%s: expected include to match HK-1", name)
+			}
+			if f.exclude != nil {
+				t.Errorf("%s: expected no exclude", name)
+			}
+		}
+	})
+
+	t.Run("map form applies per-provider filters", func(t *testing.T) {
+		config := map[string]any{
+			"filter": map[string]any{"provider1": "HK", "provider2": "US"},
+		}
+		filters, err := parseProxyFilters(config, use)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !filters["provider1"].include.MatchString("HK-1") {
+			t.Errorf("provider1: expected include to match HK-1")
+		}
+		if !filters["provider2"].include.MatchString("US-1") {
+			t.Errorf("provider2: expected include to match US-1")
+		}
+	})
+
+	t.Run("bang prefix on filter is exclude shorthand", func(t *testing.T) {
+		config := map[string]any{"filter": "!HK"}
+		filters, err := parseProxyFilters(config, use)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f := filters["provider1"]
+		if f.include != nil {
+			t.Errorf("expected no include")
+		}
+		if f.exclude == nil || !f.exclude.MatchString("HK-1") {
+			t.Errorf("expected exclude to match HK-1")
+		}
+	})
+
+	t.Run("separate exclude key", func(t *testing.T) {
+		config := map[string]any{"filter": "HK", "exclude": "HK-1"}
+		filters, err := parseProxyFilters(config, use)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f := filters["provider1"]
+		if f.include == nil || !f.include.MatchString("HK-2") {
+			t.Errorf("expected include to match HK-2")
+		}
+		if f.exclude == nil || !f.exclude.MatchString("HK-1") {
+			t.Errorf("expected exclude to match HK-1")
+		}
+	})
+
+	t.Run("invalid regex errors", func(t *testing.T) {
+		config := map[string]any{"filter": "("}
+		if _, err := parseProxyFilters(config, use); err == nil {
+			t.Errorf("expected error for invalid regex")
+		}
+	})
+
+	t.Run("invalid filter shape errors", func(t *testing.T) {
+		config := map[string]any{"filter": 1}
+		if _, err := parseProxyFilters(config, use); err == nil {
+			t.Errorf("expected error for non-string/map filter")
+		}
+	})
+}
+
+func TestParseHealthCheckURLs(t *testing.T) {
+	t.Run("nil raw falls back to single url option", func(t *testing.T) {
+		urls, err := parseHealthCheckURLs(nil, "http://example.com")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(urls) != 1 || urls[0] != "http://example.com" {
+			t.Errorf("got %v, want [http://example.com]", urls)
+		}
+	})
+
+	t.Run("nil raw and empty fallback yields no urls", func(t *testing.T) {
+		urls, err := parseHealthCheckURLs(nil, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(urls) != 0 {
+			t.Errorf("got %v, want empty", urls)
+		}
+	})
+
+	t.Run("single string", func(t *testing.T) {
+		urls, err := parseHealthCheckURLs("http://a.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(urls) != 1 || urls[0] != "http://a.com" {
+			t.Errorf("got %v, want [http://a.com]", urls)
+		}
+	})
+
+	t.Run("list of urls", func(t *testing.T) {
+		raw := []any{"http://a.com", "http://b.com"}
+		urls, err := parseHealthCheckURLs(raw, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(urls) != 2 || urls[0] != "http://a.com" || urls[1] != "http://b.com" {
+			t.Errorf("got %v, want [http://a.com http://b.com]", urls)
+		}
+	})
+
+	t.Run("list with non-string entry errors", func(t *testing.T) {
+		raw := []any{"http://a.com", 1}
+		if _, err := parseHealthCheckURLs(raw, ""); err == nil {
+			t.Errorf("expected error for non-string entry")
+		}
+	})
+
+	t.Run("unsupported type errors", func(t *testing.T) {
+		if _, err := parseHealthCheckURLs(1, ""); err == nil {
+			t.Errorf("expected error for unsupported type")
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package outboundgroup
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Dreamacro/clash/component/dialer"
+	"github.com/Dreamacro/clash/component/profile/cachefile"
+	C "github.com/Dreamacro/clash/constant"
+	types "github.com/Dreamacro/clash/constant/provider"
+)
+
+const (
+	smartTableSize      = 4096
+	smartDefaultTTL     = 30 * time.Minute
+	smartDefaultEpsilon = 0.1
+	smartEWMAAlpha      = 0.3
+
+	// smartMaxPinFailures is how many consecutive failed dials a pinned
+	// entry tolerates before it's no longer trusted. Once reached, pick
+	// stops gating on epsilon alone and re-evaluates via fast(true) every
+	// dial, so a proxy that is globally alive but broken for this one host
+	// doesn't keep getting reselected until the group's own health check
+	// happens to mark it dead.
+	smartMaxPinFailures = 3
+
+	// smartPersistInterval throttles how often the learned table is flushed
+	// to the cache DB. DialContext runs on the hot path, so it only ever
+	// schedules a flush; it never re-encodes or writes synchronously.
+	smartPersistInterval = 10 * time.Second
+)
+
+// SmartEntry is the per-destination-host record the `smart` group keeps,
+// exported so it round-trips through JSON for both the cache DB and the
+// debug endpoint.
+type SmartEntry struct {
+	Host     string    `json:"host"`
+	Proxy    string    `json:"proxy"`
+	EwmaRTT  float64   `json:"ewmaRTT"`
+	Failures int       `json:"failures"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// smartTable is an LRU-bounded host -> best-known-proxy table. Entries older
+// than ttl are treated as stale and evicted on next access.
+type smartTable struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newSmartTable(ttl time.Duration) *smartTable {
+	return &smartTable{
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (t *smartTable) get(host string) (SmartEntry, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elm, ok := t.items[host]
+	if !ok {
+		return SmartEntry{}, false
+	}
+
+	entry := elm.Value.(*SmartEntry)
+	if time.Since(entry.LastUsed) > t.ttl {
+		t.ll.Remove(elm)
+		delete(t.items, host)
+		return SmartEntry{}, false
+	}
+
+	t.ll.MoveToFront(elm)
+	return *entry, true
+}
+
+func (t *smartTable) record(host, proxy string, rtt time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elm, ok := t.items[host]
+	if !ok {
+		if t.ll.Len() >= smartTableSize {
+			if oldest := t.ll.Back(); oldest != nil {
+				t.ll.Remove(oldest)
+				delete(t.items, oldest.Value.(*SmartEntry).Host)
+			}
+		}
+		elm = t.ll.PushFront(&SmartEntry{Host: host, Proxy: proxy})
+		t.items[host] = elm
+	}
+
+	entry := elm.Value.(*SmartEntry)
+	entry.LastUsed = time.Now()
+	t.ll.MoveToFront(elm)
+
+	if failed {
+		entry.Failures++
+		return
+	}
+
+	entry.Proxy = proxy
+	entry.Failures = 0
+	ms := float64(rtt.Milliseconds())
+	if entry.EwmaRTT == 0 {
+		entry.EwmaRTT = ms
+	} else {
+		entry.EwmaRTT = smartEWMAAlpha*ms + (1-smartEWMAAlpha)*entry.EwmaRTT
+	}
+}
+
+func (t *smartTable) snapshot() []SmartEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]SmartEntry, 0, len(t.items))
+	for _, elm := range t.items {
+		out = append(out, *elm.Value.(*SmartEntry))
+	}
+	return out
+}
+
+func (t *smartTable) restore(entries []SmartEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range entries {
+		entry := entries[i]
+		if time.Since(entry.LastUsed) > t.ttl {
+			continue
+		}
+		elm := t.ll.PushFront(&entry)
+		t.items[entry.Host] = elm
+	}
+}
+
+// Smart is a url-test group that additionally learns, per destination host,
+// which proxy has historically performed best and prefers it on subsequent
+// dials instead of always re-evaluating the url-test winner.
+type Smart struct {
+	*URLTest
+	epsilon float64
+	table   *smartTable
+
+	persisting  atomic.Bool
+	lastPersist atomic.Int64 // unix nano
+}
+
+// Snapshot returns the current per-host table, for the debug endpoint.
+func (s *Smart) Snapshot() []SmartEntry {
+	return s.table.snapshot()
+}
+
+func (s *Smart) pick(host string) C.Proxy {
+	if host != "" {
+		if entry, ok := s.table.get(host); ok && entry.Failures < smartMaxPinFailures && rand.Float64() >= s.epsilon {
+			for _, proxy := range s.GetProxies(false) {
+				if proxy.Name() == entry.Proxy && proxy.Alive() {
+					return proxy
+				}
+			}
+		}
+	}
+
+	return s.fast(true)
+}
+
+func (s *Smart) DialContext(ctx context.Context, metadata *C.Metadata, opts ...dialer.Option) (C.Conn, error) {
+	host := getKey(metadata)
+	proxy := s.pick(host)
+
+	start := time.Now()
+	c, err := proxy.DialContext(ctx, metadata, s.Base.DialOptions(opts...)...)
+	if host != "" {
+		s.table.record(host, proxy.Name(), time.Since(start), err != nil)
+		s.schedulePersist()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.AppendToChains(s)
+	return c, nil
+}
+
+// schedulePersist flushes the table to the cache DB at most once every
+// smartPersistInterval, off the dial path, so a burst of concurrent dials
+// through a `smart` group doesn't turn into a burst of JSON encodes and
+// bbolt writes.
+func (s *Smart) schedulePersist() {
+	last := s.lastPersist.Load()
+	now := time.Now().UnixNano()
+	if now-last < int64(smartPersistInterval) {
+		return
+	}
+	if !s.lastPersist.CompareAndSwap(last, now) {
+		return
+	}
+	if !s.persisting.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer s.persisting.Store(false)
+		data, err := json.Marshal(s.table.snapshot())
+		if err != nil {
+			return
+		}
+		cachefile.Cache().SetSmart(s.Name(), data)
+	}()
+}
+
+func NewSmart(option *GroupCommonOption, providers []types.ProxyProvider, options ...urlTestOption) *Smart {
+	ttl := smartDefaultTTL
+	if option.SmartTTL > 0 {
+		ttl = time.Duration(option.SmartTTL) * time.Second
+	}
+
+	table := newSmartTable(ttl)
+	if data, ok := cachefile.Cache().Smart(option.Name); ok {
+		var entries []SmartEntry
+		if err := json.Unmarshal(data, &entries); err == nil {
+			table.restore(entries)
+		}
+	}
+
+	return &Smart{
+		URLTest: NewURLTest(option, providers, options...),
+		epsilon: smartDefaultEpsilon,
+		table:   table,
+	}
+}
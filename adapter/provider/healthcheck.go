@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+const defaultURLTestTimeout = 5 * time.Second
+
+// HealthCheck drives periodic URLTest probes for a group's proxies. When
+// more than one URL is configured, every URL is probed each round — in
+// round-robin order, starting one URL further along each round so no
+// single endpoint always goes first — and the round's composite score is
+// the median RTT across all of them. A hard failure against any one URL
+// marks the proxy dead for the round: probing stops there, so the proxy's
+// own Alive()/LastDelay() (driven by that last URLTest call) comes out
+// dead too. This catches a proxy that reaches one target but not another,
+// which a single-URL probe would miss.
+type HealthCheck struct {
+	proxies  []C.Proxy
+	url      []string
+	interval uint
+	lazy     bool
+	timeout  time.Duration
+
+	rrOffset uint32
+	done     chan struct{}
+}
+
+func NewHealthCheck(proxies []C.Proxy, url []string, interval uint, lazy bool, timeout time.Duration) *HealthCheck {
+	if timeout <= 0 {
+		timeout = defaultURLTestTimeout
+	}
+
+	return &HealthCheck{
+		proxies:  proxies,
+		url:      url,
+		interval: interval,
+		lazy:     lazy,
+		timeout:  timeout,
+		done:     make(chan struct{}, 1),
+	}
+}
+
+// proxyScores holds the most recent composite (median, multi-URL) RTT per
+// proxy name. It's keyed by name rather than owned by a single HealthCheck
+// because a group's alive proxies can be checked by more than one
+// HealthCheck instance (the group's own providers plus any `use`-wrapped
+// ones), and the callers ranking by it - Fallback, strategyP2C - only have
+// a proxy name to look up, not a specific HealthCheck reference.
+var proxyScores sync.Map // name string -> uint16
+
+// Score returns the most recent composite (median, multi-URL) RTT recorded
+// for a proxy by any HealthCheck, for callers that want to rank proxies by
+// it instead of LastDelay's single most-recent probe.
+func Score(name string) (uint16, bool) {
+	v, ok := proxyScores.Load(name)
+	if !ok {
+		return 0, false
+	}
+	return v.(uint16), true
+}
+
+func setScore(name string, score uint16) {
+	proxyScores.Store(name, score)
+}
+
+func (hc *HealthCheck) auto() bool {
+	return hc.interval != 0
+}
+
+func (hc *HealthCheck) process() {
+	if !hc.auto() {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(hc.interval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				hc.check()
+			case <-hc.done:
+				return
+			}
+		}
+	}()
+}
+
+func (hc *HealthCheck) close() {
+	hc.done <- struct{}{}
+}
+
+// check probes every proxy against every configured URL for this round.
+func (hc *HealthCheck) check() {
+	if len(hc.url) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hc.timeout*time.Duration(len(hc.url)))
+	defer cancel()
+
+	urls := rotate(hc.url, int(atomic.AddUint32(&hc.rrOffset, 1)))
+
+	var wg sync.WaitGroup
+	for _, proxy := range hc.proxies {
+		proxy := proxy
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hc.checkOne(ctx, proxy, urls)
+		}()
+	}
+	wg.Wait()
+}
+
+func rotate(urls []string, offset int) []string {
+	if len(urls) == 0 {
+		return urls
+	}
+	offset %= len(urls)
+	rotated := make([]string, 0, len(urls))
+	rotated = append(rotated, urls[offset:]...)
+	rotated = append(rotated, urls[:offset]...)
+	return rotated
+}
+
+// checkOne tests proxy against every URL in order. Any hard failure stops
+// the round immediately, leaving the proxy's own Alive()/LastDelay() in
+// the dead state its last (failing) URLTest call produced. When every URL
+// succeeds, the round's composite score is the median RTT across them.
+func (hc *HealthCheck) checkOne(ctx context.Context, proxy C.Proxy, urls []string) {
+	rtts := make([]uint16, 0, len(urls))
+
+	for _, u := range urls {
+		delay, err := proxy.URLTest(ctx, u)
+		if err != nil || delay == 0 {
+			return
+		}
+		rtts = append(rtts, delay)
+	}
+
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	setScore(proxy.Name(), rtts[len(rtts)/2])
+}
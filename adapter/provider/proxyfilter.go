@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"regexp"
+
+	C "github.com/Dreamacro/clash/constant"
+)
+
+// ProxyFilterProvider wraps a ProxySetProvider, exposing only the proxies
+// that match include (when set) and none that match exclude (when set).
+// Each `use` entry gets its own wrapper, so one shared provider can be
+// filtered differently per group without duplicating the provider itself.
+type ProxyFilterProvider struct {
+	*ProxySetProvider
+	name    string
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+func NewProxyFilterProvider(name string, provider *ProxySetProvider, hc *HealthCheck, include, exclude *regexp.Regexp) *ProxyFilterProvider {
+	return &ProxyFilterProvider{
+		ProxySetProvider: provider,
+		name:             name,
+		include:          include,
+		exclude:          exclude,
+	}
+}
+
+// Name implements types.ProxyProvider
+func (pf *ProxyFilterProvider) Name() string {
+	return pf.name
+}
+
+// Proxies implements types.ProxyProvider
+func (pf *ProxyFilterProvider) Proxies() []C.Proxy {
+	all := pf.ProxySetProvider.Proxies()
+	if pf.include == nil && pf.exclude == nil {
+		return all
+	}
+
+	ps := make([]C.Proxy, 0, len(all))
+	for _, proxy := range all {
+		if pf.include != nil && !pf.include.MatchString(proxy.Name()) {
+			continue
+		}
+		if pf.exclude != nil && pf.exclude.MatchString(proxy.Name()) {
+			continue
+		}
+		ps = append(ps, proxy)
+	}
+	return ps
+}
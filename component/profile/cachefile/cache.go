@@ -0,0 +1,115 @@
+package cachefile
+
+import (
+	"sync"
+
+	"github.com/Dreamacro/clash/constant"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	initOnce        sync.Once
+	fileMode        = bbolt.Mode(0o644)
+	bucketName      = "selected"
+	smartBucketName = "smart"
+)
+
+// CacheFile store and update the proxy group selected, fakeip host address
+type CacheFile struct {
+	DB *bbolt.DB
+}
+
+// SetSelected set selected proxy for specified group
+func (c *CacheFile) SetSelected(group, selected string) {
+	if c.DB == nil {
+		return
+	}
+
+	_ = c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := t.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(group), []byte(selected))
+	})
+}
+
+// SelectedMap return map of group and selected proxy
+func (c *CacheFile) SelectedMap() map[string]string {
+	if c.DB == nil {
+		return nil
+	}
+
+	mapping := map[string]string{}
+	_ = c.DB.View(func(t *bbolt.Tx) error {
+		bucket := t.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			mapping[string(k)] = string(v)
+			return nil
+		})
+	})
+	return mapping
+}
+
+// SetSmart stores the `smart` group's learned per-host table, pre-encoded
+// by the caller (cachefile is generic storage and doesn't know the shape).
+func (c *CacheFile) SetSmart(group string, data []byte) {
+	if c.DB == nil {
+		return
+	}
+
+	_ = c.DB.Batch(func(t *bbolt.Tx) error {
+		bucket, err := t.CreateBucketIfNotExists([]byte(smartBucketName))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(group), data)
+	})
+}
+
+// Smart returns the previously stored `smart` group table, if any.
+func (c *CacheFile) Smart(group string) ([]byte, bool) {
+	if c.DB == nil {
+		return nil, false
+	}
+
+	var data []byte
+	_ = c.DB.View(func(t *bbolt.Tx) error {
+		bucket := t.Bucket([]byte(smartBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		if v := bucket.Get([]byte(group)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return data, data != nil
+}
+
+var (
+	fileName string
+	instance *CacheFile
+)
+
+// Cache return singleton of CacheFile
+func Cache() *CacheFile {
+	initOnce.Do(func() {
+		db, err := bbolt.Open(constant.Path.Resolve("cache.db"), fileMode, bbolt.DefaultOptions)
+		if err != nil {
+			instance = &CacheFile{DB: nil}
+			return
+		}
+		instance = &CacheFile{DB: db}
+	})
+
+	return instance
+}
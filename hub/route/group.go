@@ -0,0 +1,39 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/Dreamacro/clash/adapter/outboundgroup"
+	"github.com/Dreamacro/clash/tunnel"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func groupRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/{name}/smart", getGroupSmartTable)
+	return r
+}
+
+// getGroupSmartTable exposes a `smart` group's learned per-destination-host
+// table for debugging why it is routing a given host the way it is.
+func getGroupSmartTable(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	proxy, ok := tunnel.Proxies()[name]
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, ErrNotFound)
+		return
+	}
+
+	smart, ok := proxy.(*outboundgroup.Smart)
+	if !ok {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError("proxy is not a smart group"))
+		return
+	}
+
+	render.JSON(w, r, render.M{"entries": smart.Snapshot()})
+}
@@ -0,0 +1,58 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/Dreamacro/clash/adapter/outboundgroup"
+	C "github.com/Dreamacro/clash/constant"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func proxyRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", getProxies)
+	r.Route("/{name}", func(r chi.Router) {
+		r.Use(parseProxyName)
+		r.Get("/", getProxy)
+		r.Put("/", updateProxy)
+		r.Get("/delay", getProxyDelay)
+	})
+	return r
+}
+
+// updateProxy handles PUT /proxies/:name. Selector and Fallback groups both
+// accept a user-pinned proxy name through the same request shape.
+func updateProxy(w http.ResponseWriter, r *http.Request) {
+	proxy := r.Context().Value(CtxKeyProxy).(C.Proxy)
+
+	req := struct {
+		Name string `json:"name"`
+	}{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, ErrBadRequest)
+		return
+	}
+
+	var err error
+	switch p := proxy.(type) {
+	case *outboundgroup.Selector:
+		err = p.Set(req.Name)
+	case *outboundgroup.Fallback:
+		err = p.Set(req.Name)
+	default:
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError("Proxy does not support select"))
+		return
+	}
+
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, newError(err.Error()))
+		return
+	}
+
+	render.NoContent(w, r)
+}
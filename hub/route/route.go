@@ -0,0 +1,17 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Router assembles every resource's sub-router into the API's top-level
+// mux. Each resource (proxies, group, ...) owns its own router so handlers
+// stay grouped with the types they operate on.
+func Router() http.Handler {
+	r := chi.NewRouter()
+	r.Mount("/proxies", proxyRouter())
+	r.Mount("/group", groupRouter())
+	return r
+}